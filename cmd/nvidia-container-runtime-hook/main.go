@@ -22,8 +22,19 @@ var (
 	versionflag   = flag.Bool("version", false, "enable version output")
 	configflag    = flag.String("config", "", "configuration file")
 	ociConfigFlag = flag.String("ociconfig", "", "oci configuration file")
+	modeflag      = flag.String("mode", "", "device injection mode: legacy (default, exec nvidia-container-cli) or native (in-process discovery)")
 )
 
+// isNativeMode returns whether device injection should be performed in-process using the
+// internal/discover package instead of exec'ing nvidia-container-cli. The --mode flag takes
+// precedence over the nvidia-container-cli.mode configuration option.
+func isNativeMode(cli CLIConfig) bool {
+	if *modeflag != "" {
+		return *modeflag == "native"
+	}
+	return cli.Mode != nil && *cli.Mode == "native"
+}
+
 func exit() {
 	if err := recover(); err != nil {
 		if _, ok := err.(runtime.Error); ok {
@@ -88,6 +99,13 @@ func doPrestart(ociConfigPath *string) {
 
 	rootfs := getRootfsPath(container)
 
+	if isNativeMode(cli) {
+		if err := doPrestartNative(hook, container, rootfs); err != nil {
+			log.Panicln("native prestart failed:", err)
+		}
+		return
+	}
+
 	args := []string{getCLIPath(cli)}
 	if cli.Root != nil {
 		args = append(args, fmt.Sprintf("--root=%s", *cli.Root))
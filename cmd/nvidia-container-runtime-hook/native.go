@@ -0,0 +1,120 @@
+/**
+# Copyright (c) 2023, NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/discover"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/edits"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/info/gpu"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/oci"
+	"github.com/NVIDIA/nvidia-container-toolkit/pkg/devicerequest"
+	"github.com/sirupsen/logrus"
+)
+
+// doPrestartNative computes the set of mounts, device nodes, and hooks required by the
+// requested container directly from the internal/discover pipeline and applies them to the
+// OCI runtime spec in-process. It is the native counterpart of the legacy path in doPrestart,
+// which instead shells out to nvidia-container-cli configure.
+func doPrestartNative(hook HookConfig, container containerConfig, rootfs string) error {
+	nvidia := container.Nvidia
+
+	if len(nvidia.MigConfigDevices) > 0 || len(nvidia.MigMonitorDevices) > 0 {
+		return fmt.Errorf("native mode does not support MIG config/monitor device exposure (mig-config=%s mig-monitor=%s); retry without --mode=native to use the legacy nvidia-container-cli path", nvidia.MigConfigDevices, nvidia.MigMonitorDevices)
+	}
+
+	logger := logrus.New()
+	if *debugflag {
+		logger.SetLevel(logrus.DebugLevel)
+	}
+
+	cfg := &discover.Config{
+		Root:                                    rootfs,
+		NVIDIAContainerToolkitCLIExecutablePath: nvidiaCTKDefaultFilePath,
+	}
+
+	// Translate the legacy NVIDIA_VISIBLE_DEVICES / NVIDIA_DRIVER_CAPABILITIES-derived fields
+	// into a structured devicerequest.DeviceRequest, and go through the same
+	// pkg/devicerequest conversions that a caller with a native DeviceRequest (e.g. a device
+	// plugin) would use, rather than re-deriving image.VisibleDevices and the capability/
+	// requirement lists by hand.
+	var requests []devicerequest.DeviceRequest
+	if len(nvidia.Devices) > 0 {
+		request := devicerequest.DeviceRequest{
+			Driver:    "nvidia",
+			DeviceIDs: strings.Split(nvidia.Devices, ","),
+		}
+		if caps := strings.Split(nvidia.DriverCapabilities, ","); len(caps) > 0 && caps[0] != "" {
+			request.Capabilities = [][]string{caps}
+		}
+		if !hook.DisableRequire && !nvidia.DisableRequire && len(nvidia.Requirements) > 0 {
+			request.Options = map[string]string{"nvidia-requirements": strings.Join(nvidia.Requirements, ",")}
+		}
+		requests = append(requests, request)
+	}
+
+	devices, err := devicerequest.ToVisibleDevices(requests, gpu.New(logger, rootfs))
+	if err != nil {
+		return fmt.Errorf("failed to resolve requested devices: %v", err)
+	}
+
+	d, err := discover.NewFromOptions(logger, devices, cfg, discover.Options{
+		DriverCapabilities: strings.Split(devicerequest.ToDriverCapabilities(requests), ","),
+		Requirements:       devicerequest.ToRequirements(requests),
+		LoadKmods:          hook.NvidiaContainerCLI.LoadKmods,
+		Ldconfig:           hook.NvidiaContainerCLI.Ldconfig,
+		NoCgroups:          hook.NvidiaContainerCLI.NoCgroups,
+		User:               hook.NvidiaContainerCLI.User,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to construct discoverer: %v", err)
+	}
+
+	specFile := oci.NewFileSpec(container.ociConfigPath())
+	spec, err := specFile.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load OCI spec: %v", err)
+	}
+
+	if bundlePath := spec.Annotations[discover.DriverBundleAnnotation]; bundlePath != "" {
+		bundle, err := discover.NewDriverBundleDiscoverer(logger, cfg, bundlePath)
+		if err != nil {
+			return fmt.Errorf("failed to process driver bundle %v: %v", bundlePath, err)
+		}
+		d = discover.Merge(d, bundle)
+	}
+
+	specEdits, err := edits.NewSpecEdits(logger, d)
+	if err != nil {
+		return fmt.Errorf("failed to create OCI spec edits: %v", err)
+	}
+
+	if err := specEdits.Modify(spec); err != nil {
+		return fmt.Errorf("failed to modify OCI spec: %v", err)
+	}
+
+	if err := specFile.Flush(spec); err != nil {
+		return fmt.Errorf("failed to flush OCI spec: %v", err)
+	}
+
+	logger.Debugf("applied native device injection for pid %s", strconv.FormatUint(uint64(container.Pid), 10))
+
+	return nil
+}
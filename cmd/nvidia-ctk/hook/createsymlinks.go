@@ -0,0 +1,81 @@
+/**
+# Copyright (c) 2023, NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package hook
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	cli "github.com/urfave/cli/v2"
+)
+
+// createSymlinksCommand implements `nvidia-ctk hook create-symlinks`, run as a CreateContainer
+// hook by internal/discover's drmDevicesByPath discoverer to recreate the host's
+// /dev/dri/by-path/pci-*-* symlinks inside the container, pointing at the container's DRM device
+// nodes.
+type createSymlinksCommand struct {
+	logger *logrus.Logger
+}
+
+// newCreateSymlinksCommand builds the `create-symlinks` command.
+func newCreateSymlinksCommand(logger *logrus.Logger) *cli.Command {
+	c := createSymlinksCommand{logger: logger}
+
+	var links cli.StringSlice
+
+	return &cli.Command{
+		Name:  "create-symlinks",
+		Usage: "Create symlinks inside the container for the discovered devices",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:        "link",
+				Usage:       "a target::link pair describing a symlink to create; may be specified multiple times",
+				Destination: &links,
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			return c.run(links.Value())
+		},
+	}
+}
+
+// run creates each requested symlink, replacing anything already present at the link path.
+func (c createSymlinksCommand) run(links []string) error {
+	for _, l := range links {
+		target, link, ok := strings.Cut(l, "::")
+		if !ok {
+			return fmt.Errorf("invalid --link %q: expected target::link", l)
+		}
+
+		c.logger.Debugf("Creating symlink %v -> %v", link, target)
+
+		if err := os.MkdirAll(filepath.Dir(link), 0755); err != nil {
+			return fmt.Errorf("failed to create directory for %v: %v", link, err)
+		}
+		if err := os.Remove(link); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove existing %v: %v", link, err)
+		}
+		if err := os.Symlink(target, link); err != nil {
+			return fmt.Errorf("failed to create symlink %v -> %v: %v", link, target, err)
+		}
+	}
+
+	return nil
+}
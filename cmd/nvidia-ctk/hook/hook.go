@@ -0,0 +1,38 @@
+/**
+# Copyright (c) 2023, NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+// Package hook implements the `nvidia-ctk hook` subcommands: the set of small, focused actions
+// that internal/discover-generated OCI CreateContainer/CreateRuntime hooks shell out to.
+// CreateContainer hooks run in the container's namespaces; CreateRuntime hooks, such as the one
+// emitted for --load-kmods, run in the runtime's namespace on the host.
+package hook
+
+import (
+	"github.com/sirupsen/logrus"
+	cli "github.com/urfave/cli/v2"
+)
+
+// NewCommand builds the `hook` command and its subcommands.
+func NewCommand(logger *logrus.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "hook",
+		Usage: "A collection of hooks that may be injected into an OCI spec",
+		Subcommands: []*cli.Command{
+			newLoadKernelModulesCommand(logger),
+			newCreateSymlinksCommand(logger),
+		},
+	}
+}
@@ -0,0 +1,94 @@
+/**
+# Copyright (c) 2023, NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package hook
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/sirupsen/logrus"
+	cli "github.com/urfave/cli/v2"
+)
+
+// loadKernelModulesCommand implements `nvidia-ctk hook load-kernel-modules`, run as a
+// CreateContainer hook (inside the container's mount and, where applicable, host PID namespace)
+// by internal/discover.NewDriverBundleDiscoverer after a sideloaded driver bundle's kernel
+// modules have been mounted in. It runs depmod against the bundle's module directory so modprobe
+// can resolve dependencies, loads each requested module, and registers the bundle's library
+// directory with ldconfig so the dynamic linker picks up the bundle's libnvidia-*.so files.
+type loadKernelModulesCommand struct {
+	logger *logrus.Logger
+}
+
+// newLoadKernelModulesCommand builds the `load-kernel-modules` command.
+func newLoadKernelModulesCommand(logger *logrus.Logger) *cli.Command {
+	c := loadKernelModulesCommand{logger: logger}
+
+	var kernelRelease string
+	var modules cli.StringSlice
+	var ldconfigPath string
+
+	return &cli.Command{
+		Name:  "load-kernel-modules",
+		Usage: "Load the kernel modules from a sideloaded driver bundle into the running kernel",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "kernel-release",
+				Usage:       "the kernel release the bundle's modules were built for, as reported by `uname -r`",
+				Destination: &kernelRelease,
+				Required:    true,
+			},
+			&cli.StringSliceFlag{
+				Name:        "module",
+				Usage:       "a kernel module to load; may be specified multiple times",
+				Destination: &modules,
+			},
+			&cli.StringFlag{
+				Name:        "ldconfig-path",
+				Usage:       "a directory of bundle libraries to register with ldconfig",
+				Destination: &ldconfigPath,
+			},
+		},
+		Action: func(ctx *cli.Context) error {
+			return c.run(kernelRelease, modules.Value(), ldconfigPath)
+		},
+	}
+}
+
+// run executes depmod, modprobe for each requested module, and ldconfig, in that order, so that
+// modprobe can resolve the bundle's module dependencies and the dynamic linker immediately picks
+// up the bundle's libraries.
+func (c loadKernelModulesCommand) run(kernelRelease string, modules []string, ldconfigPath string) error {
+	if out, err := exec.Command("depmod", "-a", kernelRelease).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to run depmod for kernel release %v: %v: %s", kernelRelease, err, out)
+	}
+
+	for _, module := range modules {
+		c.logger.Debugf("Loading kernel module %v", module)
+		if out, err := exec.Command("modprobe", module).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to load kernel module %v: %v: %s", module, err, out)
+		}
+	}
+
+	if ldconfigPath != "" {
+		if out, err := exec.Command("ldconfig", ldconfigPath).CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to run ldconfig for %v: %v: %s", ldconfigPath, err, out)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+/**
+# Copyright (c) 2023, NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package main
+
+import (
+	"os"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/cmd/nvidia-ctk/hook"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/info"
+	"github.com/sirupsen/logrus"
+	cli "github.com/urfave/cli/v2"
+)
+
+func main() {
+	logger := logrus.New()
+
+	c := cli.NewApp()
+	c.Name = "NVIDIA Container Toolkit CLI"
+	c.UseShortOptionHandling = true
+	c.EnableBashCompletion = true
+	c.Usage = "Tools to configure the NVIDIA Container Toolkit"
+	c.Version = info.GetVersionString()
+
+	c.Flags = []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "debug",
+			Usage: "enable debug-level logging",
+		},
+	}
+	c.Before = func(c *cli.Context) error {
+		if c.Bool("debug") {
+			logger.SetLevel(logrus.DebugLevel)
+		}
+		return nil
+	}
+
+	c.Commands = []*cli.Command{
+		hook.NewCommand(logger),
+	}
+
+	if err := c.Run(os.Args); err != nil {
+		logger.Errorf("%v", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,80 @@
+/**
+# Copyright (c) 2023, NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+// Package image holds the configuration derived from the container image / OCI spec that
+// governs device injection, such as the requested set of visible devices.
+package image
+
+import "strings"
+
+// VisibleDevices is the parsed representation of NVIDIA_VISIBLE_DEVICES (or an equivalent
+// structured source such as pkg/devicerequest). Each entry is either a plain identifier
+// (an index such as "0", a UUID such as "GPU-fef8...", or a PCI bus ID such as
+// "0000:65:00.0"), the "all" sentinel, or a pci=/vendor=/id= selector as described on
+// Selectors.
+type VisibleDevices []string
+
+// NewVisibleDevices builds a VisibleDevices from the given device identifiers.
+func NewVisibleDevices(ids ...string) VisibleDevices {
+	return VisibleDevices(ids)
+}
+
+// Has returns whether id is present in the visible devices list, or whether the list requests
+// "all" devices.
+func (v VisibleDevices) Has(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, d := range v {
+		if d == "all" || d == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Selectors returns the entries that use the pci=/vendor=/id= selector syntax (e.g.
+// "pci=0000:65:00.0" or "vendor=10de,id=1") rather than a plain index, UUID, or PCI bus ID.
+// These are left unresolved here; internal/discover resolves them against the host's PCI
+// topology, since doing so requires walking /sys/bus/pci/devices.
+func (v VisibleDevices) Selectors() []string {
+	var selectors []string
+	for _, d := range v {
+		if isSelectorSyntax(d) {
+			selectors = append(selectors, d)
+		}
+	}
+	return selectors
+}
+
+// isSelectorSyntax returns whether id is built entirely from pci=/vendor=/id= key=value terms.
+func isSelectorSyntax(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, term := range strings.Split(id, ",") {
+		key, _, ok := strings.Cut(term, "=")
+		if !ok {
+			return false
+		}
+		switch key {
+		case "pci", "vendor", "id":
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+/**
+# Copyright (c) 2023, NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package discover
+
+import (
+	"fmt"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/config/image"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup"
+	"github.com/sirupsen/logrus"
+)
+
+// nvidiaControlDevicePaths lists the non-GPU-specific device nodes that accompany any selected
+// NVIDIA compute device.
+var nvidiaControlDevicePaths = []string{
+	"/dev/nvidiactl",
+	"/dev/nvidia-uvm",
+	"/dev/nvidia-uvm-tools",
+	"/dev/nvidia-modeset",
+}
+
+// NewNVIDIADeviceDiscoverer returns the discoverer for the plain /dev/nvidia<minor> compute
+// device nodes (and their accompanying control devices) backing the requested visible devices.
+// This goes through the same selectedNVIDIAGPUs resolution as the DRM/graphics discoverer,
+// including pci=/vendor= selectors, so that NVIDIA_VISIBLE_DEVICES=pci=0000:65:00.0 pins the
+// actual compute device, not just its DRM render node.
+func NewNVIDIADeviceDiscoverer(logger *logrus.Logger, devices image.VisibleDevices, cfg *Config) (Discover, error) {
+	root := cfg.Root
+
+	gpus, err := selectedNVIDIAGPUs(logger, devices, root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select NVIDIA devices: %v", err)
+	}
+
+	var paths []string
+	for _, g := range gpus {
+		paths = append(paths, g.DevicePath())
+	}
+	if len(paths) > 0 {
+		paths = append(paths, nvidiaControlDevicePaths...)
+	}
+
+	return NewDeviceDiscoverer(
+		logger,
+		lookup.NewCharDeviceLocator(logger, root),
+		root,
+		paths,
+	), nil
+}
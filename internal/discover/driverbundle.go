@@ -0,0 +1,190 @@
+/**
+# Copyright (c) 2023, NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package discover
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup"
+	"github.com/container-orchestrated-devices/container-device-interface/pkg/cdi"
+	"github.com/sirupsen/logrus"
+)
+
+// DriverBundleAnnotation is the container annotation used to point at a host directory (or
+// mounted VHD) containing a driver bundle, e.g. nvidia.com/driver-bundle=/run/nvidia/driver.
+// Callers that assemble the overall discoverer for a container (e.g. doPrestartNative) check
+// the OCI spec's annotations for this key and, if present, merge in NewDriverBundleDiscoverer.
+const DriverBundleAnnotation = "nvidia.com/driver-bundle"
+
+// NewDriverBundleDiscoverer creates a discoverer for the kernel modules, libraries, and binaries
+// contained in a driver bundle at bundlePath, along with the hook required to load the kernel
+// modules and register the bundle's libraries with ldconfig inside the container.
+//
+// The bundle is expected to be laid out as:
+//
+//	<bundlePath>/lib/modules/<kver>/...
+//	<bundlePath>/usr/lib/x86_64-linux-gnu/libnvidia-*.so.*
+//	<bundlePath>/usr/bin/nvidia-smi
+//
+// This mirrors the pattern used by gpu-operator-style deployments where a driver container
+// image ships the driver artifacts and the workload container consumes them without requiring
+// the driver to be preinstalled on the host.
+func NewDriverBundleDiscoverer(logger *logrus.Logger, cfg *Config, bundlePath string) (Discover, error) {
+	kernelRelease, err := bundleKernelRelease(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine bundle kernel version: %v", err)
+	}
+
+	if err := checkKernelVersionMatches(kernelRelease); err != nil {
+		return nil, fmt.Errorf("refusing to load driver bundle %v: %v", bundlePath, err)
+	}
+
+	libraryDir := filepath.Join(bundlePath, "usr/lib/x86_64-linux-gnu")
+
+	modules := NewMounts(
+		logger,
+		lookup.NewFileLocator(logger, bundlePath),
+		bundlePath,
+		[]string{
+			filepath.Join("lib/modules", kernelRelease),
+		},
+	)
+
+	libraries := NewMounts(
+		logger,
+		lookup.NewFileLocator(logger, bundlePath),
+		bundlePath,
+		[]string{
+			filepath.Join("usr/lib/x86_64-linux-gnu", "libnvidia-*.so.*"),
+		},
+	)
+
+	binaries := NewMounts(
+		logger,
+		lookup.NewExecutableLocator(logger, bundlePath),
+		bundlePath,
+		[]string{
+			"usr/bin/nvidia-smi",
+		},
+	)
+
+	loadModules := newLoadKernelModulesHook(logger, cfg, kernelRelease, libraryDir)
+
+	return Merge(modules, libraries, binaries, loadModules), nil
+}
+
+// driverBundleModulesHook is a Discover that emits the CreateContainer hook responsible for
+// running depmod and modprobe inside the container's mount namespace, and registering the
+// bundle's library directory with ldconfig.
+type driverBundleModulesHook struct {
+	None
+	logger                  *logrus.Logger
+	lookup                  lookup.Locator
+	nvidiaCTKExecutablePath string
+	root                    string
+	kernelRelease           string
+	libraryDir              string
+}
+
+// newLoadKernelModulesHook creates the hook discoverer for loading the bundle's kernel modules.
+func newLoadKernelModulesHook(logger *logrus.Logger, cfg *Config, kernelRelease string, libraryDir string) Discover {
+	return &driverBundleModulesHook{
+		logger:                  logger,
+		lookup:                  lookup.NewExecutableLocator(logger, cfg.Root),
+		nvidiaCTKExecutablePath: cfg.NVIDIAContainerToolkitCLIExecutablePath,
+		root:                    cfg.Root,
+		kernelRelease:           kernelRelease,
+		libraryDir:              libraryDir,
+	}
+}
+
+// Hooks returns the hook that runs depmod, modprobe, and ldconfig for the sideloaded driver bundle.
+func (d driverBundleModulesHook) Hooks() ([]Hook, error) {
+	hookPath := nvidiaCTKDefaultFilePath
+	targets, err := d.lookup.Locate(d.nvidiaCTKExecutablePath)
+	if err != nil {
+		d.logger.Warnf("Failed to locate %v: %v", d.nvidiaCTKExecutablePath, err)
+	} else if len(targets) == 0 {
+		d.logger.Warnf("%v not found", d.nvidiaCTKExecutablePath)
+	} else {
+		d.logger.Debugf("Found %v candidates: %v", d.nvidiaCTKExecutablePath, targets)
+		hookPath = targets[0]
+	}
+	d.logger.Debugf("Using NVIDIA Container Toolkit CLI path %v", hookPath)
+
+	args := []string{
+		hookPath, "hook", "load-kernel-modules",
+		"--kernel-release", d.kernelRelease,
+		"--module", "nvidia",
+		"--module", "nvidia_uvm",
+		"--module", "nvidia_modeset",
+		"--ldconfig-path", d.libraryDir,
+	}
+
+	h := Hook{
+		Lifecycle: cdi.CreateContainerHook,
+		Path:      hookPath,
+		Args:      args,
+	}
+
+	return []Hook{h}, nil
+}
+
+// bundleKernelRelease returns the single kernel release directory name present under
+// <bundlePath>/lib/modules.
+func bundleKernelRelease(bundlePath string) (string, error) {
+	modulesDir := filepath.Join(bundlePath, "lib/modules")
+
+	entries, err := os.ReadDir(modulesDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %v: %v", modulesDir, err)
+	}
+
+	var releases []string
+	for _, e := range entries {
+		if e.IsDir() {
+			releases = append(releases, e.Name())
+		}
+	}
+
+	if len(releases) == 0 {
+		return "", fmt.Errorf("no kernel release directories found in %v", modulesDir)
+	}
+	if len(releases) > 1 {
+		return "", fmt.Errorf("expected a single kernel release directory in %v, found %v", modulesDir, releases)
+	}
+
+	return releases[0], nil
+}
+
+// checkKernelVersionMatches returns an error if the bundle's kernel release does not match the
+// release of the kernel currently running on the host, since loading mismatched kernel modules
+// corrupts the host's module dependency graph.
+func checkKernelVersionMatches(bundleKernelRelease string) error {
+	runningRelease, err := runningKernelRelease()
+	if err != nil {
+		return fmt.Errorf("failed to determine running kernel version: %v", err)
+	}
+
+	if runningRelease != bundleKernelRelease {
+		return fmt.Errorf("bundle kernel release %q does not match running kernel release %q", bundleKernelRelease, runningRelease)
+	}
+
+	return nil
+}
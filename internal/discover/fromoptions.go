@@ -0,0 +1,121 @@
+/**
+# Copyright (c) 2023, NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package discover
+
+import (
+	"fmt"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/config/image"
+	"github.com/sirupsen/logrus"
+)
+
+// Options captures the feature flags historically passed as nvidia-container-cli arguments, so
+// that the native prestart path can request the same behavior in-process.
+type Options struct {
+	// DriverCapabilities is the list of requested driver capabilities (e.g. "compute", "utility").
+	DriverCapabilities []string
+	// Requirements is the list of `--require=` style constraints (e.g. "cuda>=11.0").
+	Requirements []string
+	// LoadKmods mirrors nvidia-container-cli's --load-kmods.
+	LoadKmods bool
+	// Ldconfig mirrors nvidia-container-cli's --ldconfig=.
+	Ldconfig *string
+	// NoCgroups mirrors nvidia-container-cli's --no-cgroups.
+	NoCgroups bool
+	// User mirrors nvidia-container-cli's --user=.
+	User *string
+}
+
+// recognizedDriverCapabilities is the set of driver capability names accepted by
+// NVIDIA_DRIVER_CAPABILITIES / nvidia-container-cli.
+var recognizedDriverCapabilities = map[string]bool{
+	"compute":  true,
+	"compat32": true,
+	"graphics": true,
+	"utility":  true,
+	"video":    true,
+	"display":  true,
+	"ngx":      true,
+}
+
+// requestsGraphics returns whether the graphics or display capabilities, which gate DRM device
+// node and Vulkan/EGL library injection, were requested.
+func requestsGraphics(capabilities []string) bool {
+	for _, c := range capabilities {
+		if c == "graphics" || c == "display" {
+			return true
+		}
+	}
+	return false
+}
+
+// NewFromOptions builds the combined discoverer for a container's requested devices, covering
+// the same device exposure as nvidia-container-cli configure: the plain NVIDIA compute device
+// nodes, the DRM/graphics devices and libraries (gated by opts.DriverCapabilities, as
+// nvidia-container-cli gates them), and the --load-kmods/--ldconfig/--user flags.
+//
+// opts.Requirements (`--require=` constraints) are not evaluated natively, since doing so means
+// comparing against driver/CUDA version information this package does not yet source; rather
+// than silently skip the check, native mode refuses to start a container that sets them.
+// opts.NoCgroups is rejected for the same reason: this package has no way to suppress the device
+// cgroup rules that edits.NewSpecEdits derives from the discoverer's Devices().
+func NewFromOptions(logger *logrus.Logger, devices image.VisibleDevices, cfg *Config, opts Options) (Discover, error) {
+	if opts.NoCgroups {
+		return nil, fmt.Errorf("native mode does not support --no-cgroups; retry without --mode=native to use the legacy nvidia-container-cli path")
+	}
+	if len(opts.Requirements) > 0 {
+		return nil, fmt.Errorf("native mode does not support --require constraints (%v); retry without --mode=native to use the legacy nvidia-container-cli path", opts.Requirements)
+	}
+
+	var capabilities []string
+	for _, c := range opts.DriverCapabilities {
+		if c == "" {
+			continue
+		}
+		if !recognizedDriverCapabilities[c] {
+			return nil, fmt.Errorf("unrecognized driver capability %q", c)
+		}
+		capabilities = append(capabilities, c)
+	}
+
+	d, err := NewNVIDIADeviceDiscoverer(logger, devices, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create device discoverer: %v", err)
+	}
+
+	if requestsGraphics(capabilities) {
+		graphics, err := NewGraphicsDiscoverer(logger, devices, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create graphics discoverer: %v", err)
+		}
+		d = Merge(d, graphics)
+	}
+
+	if opts.LoadKmods {
+		d = Merge(d, newLoadKmodsHook(logger, cfg))
+	}
+
+	if opts.Ldconfig != nil {
+		d = Merge(d, newLdconfigHook(*opts.Ldconfig))
+	}
+
+	if opts.User != nil {
+		d = Merge(d, newChownDevicesHook(logger, cfg, d, *opts.User))
+	}
+
+	return d, nil
+}
@@ -20,49 +20,94 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/config/image"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/info/drm"
-	"github.com/NVIDIA/nvidia-container-toolkit/internal/info/proc"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/info/gpu"
 	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup"
 	"github.com/container-orchestrated-devices/container-device-interface/pkg/cdi"
 	"github.com/sirupsen/logrus"
 )
 
+// GraphicsVendor abstracts vendor-specific DRM device discovery so that NewGraphicsDiscoverer
+// is not hardcoded to NVIDIA hardware. Implementations are registered in allGraphicsVendors below.
+type GraphicsVendor interface {
+	// Name is the short, lowercase identifier used to opt a vendor's devices in via the visible
+	// devices list, e.g. "nvidia" or "intel".
+	Name() string
+	// MatchesDevice returns whether the PCI device at pciBusID, with the specified sysfs
+	// vendor and device IDs, is owned by this vendor.
+	MatchesDevice(pciBusID, vendorID, deviceID string) bool
+	// Libraries returns the library names that must be mounted for this vendor's devices to work.
+	Libraries() []string
+	// ICDJSONs returns the EGL / Vulkan ICD JSON files that must be mounted for this vendor's devices.
+	ICDJSONs() []string
+	// DRMBusIDs returns the PCI bus IDs of all DRM-capable devices owned by this vendor.
+	DRMBusIDs(root string) ([]string, error)
+}
+
+// allGraphicsVendors returns the supported GraphicsVendor implementations. NVIDIA devices are
+// always considered; other vendors are only included when explicitly requested (see
+// selectedGraphicsVendors).
+func allGraphicsVendors(logger *logrus.Logger) []GraphicsVendor {
+	return []GraphicsVendor{
+		newNvidiaGraphicsVendor(logger),
+		intelGraphicsVendor{},
+	}
+}
+
+// selectedGraphicsVendors returns the set of vendors whose devices should be discovered for the
+// requested visible devices. NVIDIA is always included; other vendors opt in by name, e.g.
+// NVIDIA_VISIBLE_DEVICES=all,intel additionally requests the integrated Intel GPU.
+func selectedGraphicsVendors(logger *logrus.Logger, devices image.VisibleDevices) []GraphicsVendor {
+	requested := make(map[string]bool)
+	for _, d := range devices {
+		requested[d] = true
+	}
+
+	var selected []GraphicsVendor
+	for _, v := range allGraphicsVendors(logger) {
+		if v.Name() == "nvidia" || requested[v.Name()] {
+			selected = append(selected, v)
+		}
+	}
+	return selected
+}
+
 // NewGraphicsDiscoverer returns the discoverer for graphics tools such as Vulkan.
 func NewGraphicsDiscoverer(logger *logrus.Logger, devices image.VisibleDevices, cfg *Config) (Discover, error) {
 	root := cfg.Root
+	vendors := selectedGraphicsVendors(logger, devices)
 
 	locator, err := lookup.NewLibraryLocator(logger, root)
 	if err != nil {
 		return nil, fmt.Errorf("failed to construct library locator: %v", err)
 	}
+
+	var libraryNames []string
+	var jsonPaths []string
+	for _, v := range vendors {
+		libraryNames = append(libraryNames, v.Libraries()...)
+		jsonPaths = append(jsonPaths, v.ICDJSONs()...)
+	}
+
 	libraries := NewMounts(
 		logger,
 		locator,
 		root,
-		[]string{
-			"libnvidia-egl-gbm.so",
-		},
+		libraryNames,
 	)
 
 	jsonMounts := NewMounts(
 		logger,
 		lookup.NewFileLocator(logger, root),
 		root,
-		[]string{
-			// TODO: We should handle this more cleanly
-			"/etc/glvnd/egl_vendor.d/10_nvidia.json",
-			"/etc/vulkan/icd.d/nvidia_icd.json",
-			"/etc/vulkan/implicit_layer.d/nvidia_layers.json",
-			"/usr/share/glvnd/egl_vendor.d/10_nvidia.json",
-			"/usr/share/vulkan/icd.d/nvidia_icd.json",
-			"/usr/share/vulkan/implicit_layer.d/nvidia_layers.json",
-			"/usr/share/egl/egl_external_platform.d/15_nvidia_gbm.json",
-		},
+		jsonPaths,
 	)
 
-	drmDeviceNodes, err := newDRMDeviceDiscoverer(logger, devices, root)
+	drmDeviceNodes, err := newDRMDeviceDiscoverer(logger, devices, root, vendors)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create DRM device discoverer: %v", err)
 	}
@@ -171,7 +216,7 @@ func (d drmDevicesByPath) getSpecificLinkArgs(devices []Device) ([]string, error
 }
 
 // newDRMDeviceDiscoverer creates a discoverer for the DRM devices associated with the requested devices.
-func newDRMDeviceDiscoverer(logger *logrus.Logger, devices image.VisibleDevices, root string) (Discover, error) {
+func newDRMDeviceDiscoverer(logger *logrus.Logger, devices image.VisibleDevices, root string, vendors []GraphicsVendor) (Discover, error) {
 	allDevices := NewDeviceDiscoverer(
 		logger,
 		lookup.NewCharDeviceLocator(logger, root),
@@ -182,7 +227,7 @@ func newDRMDeviceDiscoverer(logger *logrus.Logger, devices image.VisibleDevices,
 		},
 	)
 
-	filter, err := newDRMDeviceFilter(logger, devices, root)
+	filter, err := newDRMDeviceFilter(logger, devices, root, vendors)
 	if err != nil {
 		return nil, fmt.Errorf("failed to construct DRM device filter: %v", err)
 	}
@@ -197,26 +242,28 @@ func newDRMDeviceDiscoverer(logger *logrus.Logger, devices image.VisibleDevices,
 	return d, err
 }
 
-// newDRMDeviceFilter creates a filter that matches DRM devices nodes for the visible devices.
-func newDRMDeviceFilter(logger *logrus.Logger, devices image.VisibleDevices, root string) (Filter, error) {
-	gpuInformationPaths, err := proc.GetInformationFilePaths(root)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read GPU information: %v", err)
-	}
-
+// newDRMDeviceFilter creates a filter that matches DRM devices nodes for the visible devices,
+// across all of the specified vendors.
+func newDRMDeviceFilter(logger *logrus.Logger, devices image.VisibleDevices, root string, vendors []GraphicsVendor) (Filter, error) {
 	var selectedBusIds []string
-	for _, f := range gpuInformationPaths {
-		info, err := proc.ParseGPUInformationFile(f)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse %v: %v", f, err)
+	for _, v := range vendors {
+		if nv, ok := v.(nvidiaGraphicsVendor); ok {
+			busIds, err := nv.selectedBusIDs(devices, root)
+			if err != nil {
+				return nil, fmt.Errorf("failed to determine selected nvidia DRM devices: %v", err)
+			}
+			selectedBusIds = append(selectedBusIds, busIds...)
+			continue
 		}
-		uuid := info[proc.GPUInfoGPUUUID]
-		busID := info[proc.GPUInfoBusLocation]
-		minor := info[proc.GPUInfoDeviceMinor]
 
-		if devices.Has(minor) || devices.Has(uuid) || devices.Has(busID) {
-			selectedBusIds = append(selectedBusIds, busID)
+		// Non-NVIDIA vendors are not addressable by index, UUID, or bus ID through
+		// image.VisibleDevices; requesting the vendor by name (see selectedGraphicsVendors)
+		// selects all of that vendor's DRM-capable devices.
+		busIds, err := v.DRMBusIDs(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine %s DRM devices: %v", v.Name(), err)
 		}
+		selectedBusIds = append(selectedBusIds, busIds...)
 	}
 
 	filter := make(selectDeviceByPath)
@@ -252,3 +299,227 @@ func (s selectDeviceByPath) MountIsSelected(Mount) bool {
 func (s selectDeviceByPath) HookIsSelected(Hook) bool {
 	return true
 }
+
+// nvidiaPCIVendorID is the PCI vendor ID assigned to NVIDIA Corporation.
+const nvidiaPCIVendorID = "0x10de"
+
+// nvidiaGraphicsVendor is the GraphicsVendor implementation for NVIDIA GPUs. Device selection
+// goes through the internal/info/gpu abstraction, which prefers NVML over parsing
+// /proc/driver/nvidia and, critically, does not assume that a requested index identifies the
+// same device as the device minor.
+type nvidiaGraphicsVendor struct {
+	logger *logrus.Logger
+}
+
+var _ GraphicsVendor = (*nvidiaGraphicsVendor)(nil)
+
+// newNvidiaGraphicsVendor constructs the NVIDIA GraphicsVendor.
+func newNvidiaGraphicsVendor(logger *logrus.Logger) nvidiaGraphicsVendor {
+	return nvidiaGraphicsVendor{logger: logger}
+}
+
+// Name returns the vendor name used for opting in via image.VisibleDevices.
+func (v nvidiaGraphicsVendor) Name() string {
+	return "nvidia"
+}
+
+// MatchesDevice returns whether the specified sysfs vendor ID belongs to NVIDIA.
+func (v nvidiaGraphicsVendor) MatchesDevice(pciBusID, vendorID, deviceID string) bool {
+	return vendorID == nvidiaPCIVendorID
+}
+
+// Libraries returns the libraries required for NVIDIA-backed Vulkan / EGL graphics.
+func (v nvidiaGraphicsVendor) Libraries() []string {
+	return []string{
+		"libnvidia-egl-gbm.so",
+	}
+}
+
+// ICDJSONs returns the EGL / Vulkan ICD JSON files required for NVIDIA graphics.
+func (v nvidiaGraphicsVendor) ICDJSONs() []string {
+	// TODO: We should handle this more cleanly
+	return []string{
+		"/etc/glvnd/egl_vendor.d/10_nvidia.json",
+		"/etc/vulkan/icd.d/nvidia_icd.json",
+		"/etc/vulkan/implicit_layer.d/nvidia_layers.json",
+		"/usr/share/glvnd/egl_vendor.d/10_nvidia.json",
+		"/usr/share/vulkan/icd.d/nvidia_icd.json",
+		"/usr/share/vulkan/implicit_layer.d/nvidia_layers.json",
+		"/usr/share/egl/egl_external_platform.d/15_nvidia_gbm.json",
+	}
+}
+
+// DRMBusIDs returns the bus IDs of all NVIDIA GPUs known to the gpu package.
+func (v nvidiaGraphicsVendor) DRMBusIDs(root string) ([]string, error) {
+	gpus, err := selectedNVIDIAGPUs(v.logger, image.NewVisibleDevices("all"), root)
+	if err != nil {
+		return nil, err
+	}
+
+	var busIDs []string
+	for _, g := range gpus {
+		busIDs = append(busIDs, g.PCIBusID)
+	}
+	return busIDs, nil
+}
+
+// selectedBusIDs returns the bus IDs of NVIDIA GPUs matching the specified visible devices.
+func (v nvidiaGraphicsVendor) selectedBusIDs(devices image.VisibleDevices, root string) ([]string, error) {
+	gpus, err := selectedNVIDIAGPUs(v.logger, devices, root)
+	if err != nil {
+		return nil, err
+	}
+
+	var busIDs []string
+	for _, g := range gpus {
+		busIDs = append(busIDs, g.PCIBusID)
+	}
+	return busIDs, nil
+}
+
+// selectedNVIDIAGPUs returns the GPUs, enumerated via the internal/info/gpu abstraction, that
+// match the requested visible devices. A nil devices selects none, matching the behavior of
+// image.VisibleDevices.Has on an empty set.
+//
+// An index selector (e.g. NVIDIA_VISIBLE_DEVICES=0) is resolved against each GPU's
+// PCIe-enumeration index, not its device minor, since the two are not guaranteed to coincide.
+// pci=/vendor= selectors (see pciselector.go) are resolved by walking /sys/bus/pci/devices and
+// matched against each GPU's PCI bus ID. This is the single selection path shared by DRM/graphics
+// discovery and the plain /dev/nvidia<minor> compute device discoverer (see devices.go), so that
+// a selector resolves consistently for both.
+func selectedNVIDIAGPUs(logger *logrus.Logger, devices image.VisibleDevices, root string) ([]gpu.Info, error) {
+	gpus, err := gpu.New(logger, root).GPUs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to enumerate GPUs: %v", err)
+	}
+
+	selectorBusIDs, err := resolveDeviceSelectors(devices, root)
+	if err != nil {
+		return nil, err
+	}
+	selected := make(map[string]bool)
+	for _, busID := range selectorBusIDs {
+		selected[busID] = true
+	}
+
+	var matched []gpu.Info
+	for _, g := range gpus {
+		index := strconv.Itoa(g.Index)
+		if devices.Has(index) || devices.Has(g.UUID) || devices.Has(g.PCIBusID) || selected[g.PCIBusID] || hasSelectedMIGInstance(devices, g.MIGInstances) {
+			matched = append(matched, g)
+		}
+	}
+
+	return matched, nil
+}
+
+// hasSelectedMIGInstance returns whether devices requests any of the given MIG instance UUIDs. A
+// MIG instance is not backed by its own device node, so selecting one resolves to its parent
+// GPU's /dev/nvidia<minor> (the node MIG instances are multiplexed through), rather than being
+// silently dropped.
+func hasSelectedMIGInstance(devices image.VisibleDevices, migInstances []string) bool {
+	for _, uuid := range migInstances {
+		if devices.Has(uuid) {
+			return true
+		}
+	}
+	return false
+}
+
+// intelPCIVendorID is the PCI vendor ID assigned to Intel Corporation.
+const intelPCIVendorID = "0x8086"
+
+// intelGraphicsVendor is the GraphicsVendor implementation for Intel integrated GPUs, discovered
+// through sysfs rather than a vendor-specific proc interface.
+type intelGraphicsVendor struct{}
+
+var _ GraphicsVendor = (*intelGraphicsVendor)(nil)
+
+// Name returns the vendor name used for opting in via image.VisibleDevices.
+func (v intelGraphicsVendor) Name() string {
+	return "intel"
+}
+
+// MatchesDevice returns whether the specified sysfs vendor ID belongs to Intel.
+func (v intelGraphicsVendor) MatchesDevice(pciBusID, vendorID, deviceID string) bool {
+	return vendorID == intelPCIVendorID
+}
+
+// Libraries returns the libraries required for Intel-backed Vulkan / EGL graphics.
+func (v intelGraphicsVendor) Libraries() []string {
+	return []string{
+		"libgbm.so",
+	}
+}
+
+// ICDJSONs returns the EGL / Vulkan ICD JSON files required for Intel graphics.
+func (v intelGraphicsVendor) ICDJSONs() []string {
+	return []string{
+		"/usr/share/glvnd/egl_vendor.d/50_mesa.json",
+		"/usr/share/vulkan/icd.d/intel_icd.x86_64.json",
+	}
+}
+
+// DRMBusIDs returns the PCI bus IDs of all DRM-capable Intel devices, discovered by walking
+// /sys/bus/pci/devices the way LXD's gpu device does.
+func (v intelGraphicsVendor) DRMBusIDs(root string) ([]string, error) {
+	return pciDRMBusIDsMatching(root, v.MatchesDevice)
+}
+
+// pciDRMBusIDsMatching walks /sys/bus/pci/devices looking for devices with a drm subdirectory
+// (i.e. devices that expose /dev/dri nodes) whose vendor/device sysfs files satisfy match.
+func pciDRMBusIDsMatching(root string, match func(pciBusID, vendorID, deviceID string) bool) ([]string, error) {
+	return pciDevicesMatching(root, true, match)
+}
+
+// pciDevicesMatching walks /sys/bus/pci/devices looking for devices whose vendor/device sysfs
+// files satisfy match. If requireDRM is set, only devices exposing a drm subdirectory (i.e.
+// devices with /dev/dri nodes) are considered.
+func pciDevicesMatching(root string, requireDRM bool, match func(pciBusID, vendorID, deviceID string) bool) ([]string, error) {
+	pciDevicesRoot := filepath.Join(root, "/sys/bus/pci/devices")
+
+	entries, err := os.ReadDir(pciDevicesRoot)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %v: %v", pciDevicesRoot, err)
+	}
+
+	var busIDs []string
+	for _, entry := range entries {
+		busID := entry.Name()
+		devicePath := filepath.Join(pciDevicesRoot, busID)
+
+		if requireDRM {
+			if drmEntries, err := os.ReadDir(filepath.Join(devicePath, "drm")); err != nil || len(drmEntries) == 0 {
+				continue
+			}
+		}
+
+		vendorID, err := readSysfsID(filepath.Join(devicePath, "vendor"))
+		if err != nil {
+			continue
+		}
+		deviceID, err := readSysfsID(filepath.Join(devicePath, "device"))
+		if err != nil {
+			continue
+		}
+
+		if match(busID, vendorID, deviceID) {
+			busIDs = append(busIDs, busID)
+		}
+	}
+
+	return busIDs, nil
+}
+
+// readSysfsID reads a sysfs file containing a single hex ID (e.g. "0x10de\n") and returns its
+// trimmed contents.
+func readSysfsID(path string) (string, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(contents)), nil
+}
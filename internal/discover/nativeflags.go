@@ -0,0 +1,180 @@
+/**
+# Copyright (c) 2023, NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package discover
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/lookup"
+	"github.com/container-orchestrated-devices/container-device-interface/pkg/cdi"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// loadKmodsHook is a Discover that emits the CreateRuntime hook responsible for loading the core
+// NVIDIA kernel modules on the host before the container's devices are created, mirroring
+// nvidia-container-cli's --load-kmods. It reuses the same nvidia-ctk hook load-kernel-modules
+// subcommand that NewDriverBundleDiscoverer shells out to.
+type loadKmodsHook struct {
+	None
+	logger                  *logrus.Logger
+	lookup                  lookup.Locator
+	nvidiaCTKExecutablePath string
+}
+
+// newLoadKmodsHook creates the hook discoverer for --load-kmods.
+func newLoadKmodsHook(logger *logrus.Logger, cfg *Config) Discover {
+	return &loadKmodsHook{
+		logger:                  logger,
+		lookup:                  lookup.NewExecutableLocator(logger, cfg.Root),
+		nvidiaCTKExecutablePath: cfg.NVIDIAContainerToolkitCLIExecutablePath,
+	}
+}
+
+// Hooks returns the hook that loads the core NVIDIA kernel modules on the host.
+func (d loadKmodsHook) Hooks() ([]Hook, error) {
+	kernelRelease, err := runningKernelRelease()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine running kernel release: %v", err)
+	}
+
+	hookPath := d.resolveNVIDIACTKPath()
+
+	args := []string{
+		hookPath, "hook", "load-kernel-modules",
+		"--kernel-release", kernelRelease,
+		"--module", "nvidia",
+		"--module", "nvidia_uvm",
+		"--module", "nvidia_modeset",
+	}
+
+	h := Hook{
+		Lifecycle: cdi.CreateRuntimeHook,
+		Path:      hookPath,
+		Args:      args,
+	}
+
+	return []Hook{h}, nil
+}
+
+// resolveNVIDIACTKPath locates the nvidia-ctk executable, falling back to nvidiaCTKDefaultFilePath
+// if it cannot be found.
+func (d loadKmodsHook) resolveNVIDIACTKPath() string {
+	hookPath := nvidiaCTKDefaultFilePath
+	targets, err := d.lookup.Locate(d.nvidiaCTKExecutablePath)
+	if err != nil {
+		d.logger.Warnf("Failed to locate %v: %v", d.nvidiaCTKExecutablePath, err)
+	} else if len(targets) == 0 {
+		d.logger.Warnf("%v not found", d.nvidiaCTKExecutablePath)
+	} else {
+		d.logger.Debugf("Found %v candidates: %v", d.nvidiaCTKExecutablePath, targets)
+		hookPath = targets[0]
+	}
+	return hookPath
+}
+
+// runningKernelRelease returns the release of the kernel currently running on the host, as
+// reported by uname.
+func runningKernelRelease() (string, error) {
+	var uname unix.Utsname
+	if err := unix.Uname(&uname); err != nil {
+		return "", fmt.Errorf("failed to call uname: %v", err)
+	}
+	return unix.ByteSliceToString(uname.Release[:]), nil
+}
+
+// ldconfigHook is a Discover that emits the CreateContainer hook that runs the specified
+// ldconfig binary inside the container to refresh the dynamic linker cache after the driver
+// libraries have been mounted in, mirroring nvidia-container-cli's --ldconfig=. A leading '@' in
+// ldconfigPath, which nvidia-container-cli uses to mean "resolve this path on the host rather
+// than inside the container rootfs", is stripped since the hook already executes inside the
+// container's mount namespace.
+type ldconfigHook struct {
+	None
+	ldconfigPath string
+}
+
+// newLdconfigHook creates the hook discoverer for --ldconfig=.
+func newLdconfigHook(ldconfigPath string) Discover {
+	return &ldconfigHook{ldconfigPath: strings.TrimPrefix(ldconfigPath, "@")}
+}
+
+// Hooks returns the hook that re-runs ldconfig inside the container.
+func (d ldconfigHook) Hooks() ([]Hook, error) {
+	h := Hook{
+		Lifecycle: cdi.CreateContainerHook,
+		Path:      d.ldconfigPath,
+		Args:      []string{d.ldconfigPath},
+	}
+	return []Hook{h}, nil
+}
+
+// chownDevicesHook is a Discover that emits the CreateContainer hook that chowns the discovered
+// device nodes to the requested user, mirroring nvidia-container-cli's --user=.
+type chownDevicesHook struct {
+	None
+	logger      *logrus.Logger
+	lookup      lookup.Locator
+	devicesFrom Discover
+	user        string
+}
+
+// newChownDevicesHook creates the hook discoverer for --user=. devicesFrom is queried for its
+// Devices() at hook-emission time, so it must already include every device node that should be
+// chowned (i.e. the full discoverer built so far, not just the NVIDIA compute devices).
+func newChownDevicesHook(logger *logrus.Logger, cfg *Config, devicesFrom Discover, user string) Discover {
+	return &chownDevicesHook{
+		logger:      logger,
+		lookup:      lookup.NewExecutableLocator(logger, cfg.Root),
+		devicesFrom: devicesFrom,
+		user:        user,
+	}
+}
+
+// Hooks returns the hook that chowns the discovered device nodes to the requested user.
+func (d chownDevicesHook) Hooks() ([]Hook, error) {
+	devices, err := d.devicesFrom.Devices()
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover devices to chown: %v", err)
+	}
+	if len(devices) == 0 {
+		return nil, nil
+	}
+
+	targets, err := d.lookup.Locate("chown")
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate chown: %v", err)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("chown not found")
+	}
+	chownPath := targets[0]
+
+	args := []string{chownPath, d.user}
+	for _, dev := range devices {
+		args = append(args, dev.Path)
+	}
+
+	h := Hook{
+		Lifecycle: cdi.CreateContainerHook,
+		Path:      chownPath,
+		Args:      args,
+	}
+
+	return []Hook{h}, nil
+}
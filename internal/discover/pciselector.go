@@ -0,0 +1,115 @@
+/**
+# Copyright (c) 2023, NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package discover
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/config/image"
+)
+
+// deviceSelector is a parsed PCI-address or vendor:device selector, modeled on the syntax
+// accepted by LXD's gpu device: pci=0000:65:00.0, vendor=10de, or vendor=10de,id=1 to pick the
+// nth (0-indexed) matching card. image.VisibleDevices is expected to recognize this syntax and
+// expose the unparsed selector segments through Selectors(), alongside the index/UUID/bus-ID
+// entries it already resolves itself.
+type deviceSelector struct {
+	pciBusID string
+	vendorID string
+	nth      int
+}
+
+// parseDeviceSelector parses a single comma-combined selector such as "vendor=10de,id=1". It
+// returns ok=false for anything that isn't built entirely from pci=/vendor=/id= terms, since
+// those are handled elsewhere as plain index, UUID, or bus ID entries.
+func parseDeviceSelector(raw string) (deviceSelector, bool) {
+	selector := deviceSelector{nth: -1}
+
+	for _, term := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(term, "=")
+		if !ok {
+			return deviceSelector{}, false
+		}
+
+		switch key {
+		case "pci":
+			selector.pciBusID = value
+		case "vendor":
+			selector.vendorID = value
+		case "id":
+			nth, err := strconv.Atoi(value)
+			if err != nil {
+				return deviceSelector{}, false
+			}
+			selector.nth = nth
+		default:
+			return deviceSelector{}, false
+		}
+	}
+
+	if selector.pciBusID == "" && selector.vendorID == "" {
+		return deviceSelector{}, false
+	}
+
+	return selector, true
+}
+
+// resolve returns the PCI bus IDs of the devices, rooted at root, that satisfy the selector.
+func (s deviceSelector) resolve(root string) ([]string, error) {
+	matches, err := pciDevicesMatching(root, false, func(pciBusID, vendorID, deviceID string) bool {
+		if s.pciBusID != "" && pciBusID != s.pciBusID {
+			return false
+		}
+		if s.vendorID != "" && vendorID != "0x"+s.vendorID {
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.nth < 0 {
+		return matches, nil
+	}
+	if s.nth >= len(matches) {
+		return nil, fmt.Errorf("selector requests card %d but only %d matching devices were found", s.nth, len(matches))
+	}
+
+	return []string{matches[s.nth]}, nil
+}
+
+// resolveDeviceSelectors resolves the pci=/vendor= selectors present in devices to PCI bus IDs.
+func resolveDeviceSelectors(devices image.VisibleDevices, root string) ([]string, error) {
+	var busIDs []string
+	for _, raw := range devices.Selectors() {
+		selector, ok := parseDeviceSelector(raw)
+		if !ok {
+			continue
+		}
+
+		matches, err := selector.resolve(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve device selector %q: %v", raw, err)
+		}
+		busIDs = append(busIDs, matches...)
+	}
+
+	return busIDs, nil
+}
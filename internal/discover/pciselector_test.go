@@ -0,0 +1,146 @@
+/**
+# Copyright (c) 2023, NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package discover
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseDeviceSelector(t *testing.T) {
+	testCases := []struct {
+		description string
+		raw         string
+		ok          bool
+		expected    deviceSelector
+	}{
+		{
+			description: "pci selector",
+			raw:         "pci=0000:65:00.0",
+			ok:          true,
+			expected:    deviceSelector{pciBusID: "0000:65:00.0", nth: -1},
+		},
+		{
+			description: "vendor selector",
+			raw:         "vendor=10de",
+			ok:          true,
+			expected:    deviceSelector{vendorID: "10de", nth: -1},
+		},
+		{
+			description: "vendor selector with id",
+			raw:         "vendor=10de,id=1",
+			ok:          true,
+			expected:    deviceSelector{vendorID: "10de", nth: 1},
+		},
+		{
+			description: "plain index is not selector syntax",
+			raw:         "0",
+			ok:          false,
+		},
+		{
+			description: "UUID is not selector syntax",
+			raw:         "GPU-fef8ceb9",
+			ok:          false,
+		},
+		{
+			description: "unknown key is not selector syntax",
+			raw:         "foo=bar",
+			ok:          false,
+		},
+		{
+			description: "non-numeric id fails to parse",
+			raw:         "vendor=10de,id=abc",
+			ok:          false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			selector, ok := parseDeviceSelector(tc.raw)
+			if ok != tc.ok {
+				t.Fatalf("expected ok=%v, got %v", tc.ok, ok)
+			}
+			if ok && selector != tc.expected {
+				t.Fatalf("expected %+v, got %+v", tc.expected, selector)
+			}
+		})
+	}
+}
+
+// writeFakePCIDevice creates a fake /sys/bus/pci/devices/<busID> entry under root, optionally
+// with a drm subdirectory.
+func writeFakePCIDevice(t *testing.T, root, busID, vendorID, deviceID string, withDRM bool) {
+	t.Helper()
+
+	devicePath := filepath.Join(root, "sys/bus/pci/devices", busID)
+	if err := os.MkdirAll(devicePath, 0755); err != nil {
+		t.Fatalf("failed to create device dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(devicePath, "vendor"), []byte(vendorID+"\n"), 0644); err != nil {
+		t.Fatalf("failed to write vendor: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(devicePath, "device"), []byte(deviceID+"\n"), 0644); err != nil {
+		t.Fatalf("failed to write device: %v", err)
+	}
+	if withDRM {
+		if err := os.MkdirAll(filepath.Join(devicePath, "drm", "card0"), 0755); err != nil {
+			t.Fatalf("failed to create drm dir: %v", err)
+		}
+	}
+}
+
+func TestPCIDevicesMatching(t *testing.T) {
+	root := t.TempDir()
+	writeFakePCIDevice(t, root, "0000:65:00.0", "0x10de", "0x1eb8", true)
+	writeFakePCIDevice(t, root, "0000:00:02.0", "0x8086", "0x9bc4", true)
+	writeFakePCIDevice(t, root, "0000:66:00.0", "0x10de", "0x1eb8", false)
+
+	matchNvidia := func(pciBusID, vendorID, deviceID string) bool {
+		return vendorID == "0x10de"
+	}
+
+	t.Run("requireDRM selects only DRM-capable matches", func(t *testing.T) {
+		busIDs, err := pciDevicesMatching(root, true, matchNvidia)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(busIDs) != 1 || busIDs[0] != "0000:65:00.0" {
+			t.Fatalf("expected [0000:65:00.0], got %v", busIDs)
+		}
+	})
+
+	t.Run("without requireDRM all vendor matches are selected", func(t *testing.T) {
+		busIDs, err := pciDevicesMatching(root, false, matchNvidia)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(busIDs) != 2 {
+			t.Fatalf("expected 2 matches, got %v", busIDs)
+		}
+	})
+
+	t.Run("missing sysfs tree returns no error", func(t *testing.T) {
+		busIDs, err := pciDevicesMatching(t.TempDir(), false, matchNvidia)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(busIDs) != 0 {
+			t.Fatalf("expected no matches, got %v", busIDs)
+		}
+	})
+}
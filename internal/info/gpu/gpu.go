@@ -0,0 +1,109 @@
+/**
+# Copyright (c) 2023, NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+// Package gpu provides a backend-agnostic view of the GPUs visible on the host, used to resolve
+// the selectors accepted by image.VisibleDevices (index, UUID, PCI bus ID) to the concrete
+// /dev/nvidia<minor> device node that backs them.
+//
+// Two backends are provided: a proc backend that parses
+// /proc/driver/nvidia/gpus/*/information, and an NVML backend. The proc backend is kept for
+// environments where NVML is unavailable (e.g. rootless containers with a bind-mounted /proc);
+// the NVML backend is preferred where possible since it does not depend on a populated procfs
+// and distinguishes PCIe-enumeration index from device minor explicitly, rather than assuming
+// they are the same value.
+package gpu
+
+import "github.com/NVIDIA/nvidia-container-toolkit/internal/info/proc"
+
+// Info describes a single GPU as reported by a Source.
+type Info struct {
+	// Index is the PCIe-enumeration order of the GPU, as used by NVIDIA_VISIBLE_DEVICES=0,1,...
+	Index int
+	// Minor is the device minor number backing /dev/nvidia<Minor>. This is not guaranteed to
+	// equal Index.
+	Minor string
+	// UUID is the GPU's UUID, e.g. "GPU-fef8...".
+	UUID string
+	// PCIBusID is the GPU's PCI bus ID, e.g. "0000:65:00.0".
+	PCIBusID string
+	// MIGInstances lists the UUIDs of any MIG instances configured on this GPU.
+	MIGInstances []string
+}
+
+// DevicePath returns the /dev/nvidia<Minor> device node path for the GPU.
+func (i Info) DevicePath() string {
+	return "/dev/nvidia" + i.Minor
+}
+
+// Logger is the minimal logging interface required by this package, matching info.Logger.
+type Logger interface {
+	Infof(format string, args ...interface{})
+	Debugf(format string, args ...interface{})
+}
+
+// Source returns the set of GPUs visible on the host.
+type Source interface {
+	GPUs() ([]Info, error)
+}
+
+// New returns the best available Source for the host: an NVML-backed source if NVML can be
+// initialized, falling back to parsing /proc/driver/nvidia otherwise.
+func New(logger Logger, root string) Source {
+	if nvmlSource, err := newNVMLSource(logger); err == nil {
+		return nvmlSource
+	} else {
+		logger.Debugf("NVML source unavailable, falling back to /proc/driver/nvidia: %v", err)
+	}
+
+	return newProcSource(logger, root)
+}
+
+// procSource is a Source backed by /proc/driver/nvidia/gpus/*/information.
+type procSource struct {
+	logger Logger
+	root   string
+}
+
+func newProcSource(logger Logger, root string) Source {
+	return &procSource{logger: logger, root: root}
+}
+
+// GPUs implements Source by parsing proc information files. Since proc does not report a
+// separate PCIe-enumeration index, Index is assigned in the (sorted) order the information
+// files are returned.
+func (s *procSource) GPUs() ([]Info, error) {
+	paths, err := proc.GetInformationFilePaths(s.root)
+	if err != nil {
+		return nil, err
+	}
+
+	var gpus []Info
+	for index, path := range paths {
+		fields, err := proc.ParseGPUInformationFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		gpus = append(gpus, Info{
+			Index:    index,
+			Minor:    fields[proc.GPUInfoDeviceMinor],
+			UUID:     fields[proc.GPUInfoGPUUUID],
+			PCIBusID: fields[proc.GPUInfoBusLocation],
+		})
+	}
+
+	return gpus, nil
+}
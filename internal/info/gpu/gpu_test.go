@@ -0,0 +1,102 @@
+/**
+# Copyright (c) 2023, NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package gpu
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testLogger is a no-op Logger used by tests that don't care about log output.
+type testLogger struct{}
+
+func (testLogger) Infof(format string, args ...interface{})  {}
+func (testLogger) Debugf(format string, args ...interface{}) {}
+
+// writeFakeProcGPUInformation creates a fake /proc/driver/nvidia/gpus/<busID>/information file
+// under root, in the same key/value format the real driver reports.
+func writeFakeProcGPUInformation(t *testing.T, root, busID, uuid, minor string) {
+	t.Helper()
+
+	dir := filepath.Join(root, "proc/driver/nvidia/gpus", busID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create %v: %v", dir, err)
+	}
+
+	contents := fmt.Sprintf(
+		"Model: \t\t\t Tesla T4\nIRQ:   \t\t\t 41\nGPU UUID: \t\t %s\nVideo BIOS: \t\t 90.04.96.00.06\nBus Location: \t\t %s\nDevice Minor: \t\t %s\n",
+		uuid, busID, minor,
+	)
+	if err := os.WriteFile(filepath.Join(dir, "information"), []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write information file: %v", err)
+	}
+}
+
+func TestProcSourceGPUs(t *testing.T) {
+	root := t.TempDir()
+	writeFakeProcGPUInformation(t, root, "0000:65:00.0", "GPU-fef8ceb9-0000-0000-0000-000000000000", "0")
+	writeFakeProcGPUInformation(t, root, "0000:66:00.0", "GPU-aaaaaaaa-0000-0000-0000-000000000000", "1")
+
+	source := newProcSource(testLogger{}, root)
+	gpus, err := source.GPUs()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(gpus) != 2 {
+		t.Fatalf("expected 2 GPUs, got %d", len(gpus))
+	}
+
+	byBusID := make(map[string]Info)
+	for _, g := range gpus {
+		byBusID[g.PCIBusID] = g
+	}
+
+	first, ok := byBusID["0000:65:00.0"]
+	if !ok {
+		t.Fatalf("expected a GPU at 0000:65:00.0, got %+v", gpus)
+	}
+	if first.UUID != "GPU-fef8ceb9-0000-0000-0000-000000000000" {
+		t.Fatalf("unexpected UUID: %v", first.UUID)
+	}
+	if first.Minor != "0" {
+		t.Fatalf("unexpected minor: %v", first.Minor)
+	}
+	if first.DevicePath() != "/dev/nvidia0" {
+		t.Fatalf("unexpected device path: %v", first.DevicePath())
+	}
+
+	second, ok := byBusID["0000:66:00.0"]
+	if !ok {
+		t.Fatalf("expected a GPU at 0000:66:00.0, got %+v", gpus)
+	}
+	if second.Minor != "1" {
+		t.Fatalf("unexpected minor: %v", second.Minor)
+	}
+}
+
+func TestProcSourceGPUsEmpty(t *testing.T) {
+	source := newProcSource(testLogger{}, t.TempDir())
+	gpus, err := source.GPUs()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(gpus) != 0 {
+		t.Fatalf("expected no GPUs, got %+v", gpus)
+	}
+}
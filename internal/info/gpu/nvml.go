@@ -0,0 +1,119 @@
+/**
+# Copyright (c) 2023, NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package gpu
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/NVIDIA/go-nvml/pkg/nvml"
+)
+
+// nvmlSource is a Source backed by NVML. Unlike procSource, it does not require
+// /proc/driver/nvidia to be populated, and reports PCIe-enumeration index and device minor as
+// distinct fields rather than assuming they coincide.
+type nvmlSource struct {
+	logger Logger
+	nvml   nvml.Interface
+}
+
+// newNVMLSource constructs an nvmlSource, returning an error if NVML cannot be initialized (for
+// example because libnvidia-ml.so.1 is not present).
+func newNVMLSource(logger Logger) (Source, error) {
+	lib := nvml.New()
+	if ret := lib.Init(); ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to initialize NVML: %v", ret)
+	}
+
+	return &nvmlSource{logger: logger, nvml: lib}, nil
+}
+
+// GPUs implements Source using NVML device queries.
+func (s *nvmlSource) GPUs() ([]Info, error) {
+	defer func() {
+		if ret := s.nvml.Shutdown(); ret != nvml.SUCCESS {
+			s.logger.Debugf("failed to shut down NVML: %v", ret)
+		}
+	}()
+
+	count, ret := s.nvml.DeviceGetCount()
+	if ret != nvml.SUCCESS {
+		return nil, fmt.Errorf("failed to get device count: %v", ret)
+	}
+
+	var gpus []Info
+	for index := 0; index < count; index++ {
+		device, ret := s.nvml.DeviceGetHandleByIndex(index)
+		if ret != nvml.SUCCESS {
+			return nil, fmt.Errorf("failed to get handle for device %d: %v", index, ret)
+		}
+
+		info, err := s.infoForDevice(index, device)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get info for device %d: %v", index, err)
+		}
+
+		gpus = append(gpus, info)
+	}
+
+	return gpus, nil
+}
+
+// infoForDevice builds an Info from an NVML device handle, including any configured MIG
+// instances.
+func (s *nvmlSource) infoForDevice(index int, device nvml.Device) (Info, error) {
+	minor, ret := device.GetMinorNumber()
+	if ret != nvml.SUCCESS {
+		return Info{}, fmt.Errorf("failed to get minor number: %v", ret)
+	}
+
+	uuid, ret := device.GetUUID()
+	if ret != nvml.SUCCESS {
+		return Info{}, fmt.Errorf("failed to get UUID: %v", ret)
+	}
+
+	pciInfo, ret := device.GetPciInfo()
+	if ret != nvml.SUCCESS {
+		return Info{}, fmt.Errorf("failed to get PCI info: %v", ret)
+	}
+
+	info := Info{
+		Index:    index,
+		Minor:    strconv.Itoa(minor),
+		UUID:     uuid,
+		PCIBusID: nvml.PciInfo(pciInfo).BusId2String(),
+	}
+
+	migCount, ret := device.GetMaxMigDeviceCount()
+	if ret != nvml.SUCCESS || migCount == 0 {
+		return info, nil
+	}
+
+	for i := 0; i < migCount; i++ {
+		migDevice, ret := device.GetMigDeviceHandleByIndex(i)
+		if ret != nvml.SUCCESS {
+			continue
+		}
+		migUUID, ret := migDevice.GetUUID()
+		if ret != nvml.SUCCESS {
+			continue
+		}
+		info.MIGInstances = append(info.MIGInstances, migUUID)
+	}
+
+	return info, nil
+}
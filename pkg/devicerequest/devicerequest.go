@@ -0,0 +1,172 @@
+/**
+# Copyright (c) 2023, NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+// Package devicerequest provides a structured, programmatic alternative to configuring GPU
+// visibility and capabilities through the NVIDIA_VISIBLE_DEVICES and NVIDIA_DRIVER_CAPABILITIES
+// environment variables. It is consumed by the runtime hook's native prestart path; CDI spec
+// generation does not use it. It is intended for higher-level tooling (device plugins, non-Docker runtimes, build systems) that
+// already has a structured representation of the devices it wants and should not have to
+// synthesize an env var string to get one.
+package devicerequest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/config/image"
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/info/gpu"
+)
+
+// CountAll requests that all devices available to the specified driver be made visible.
+// It mirrors the meaning of Count == -1 in Docker's DeviceRequest.
+const CountAll = -1
+
+// DeviceRequest mirrors the shape of Docker's container.DeviceRequest. A caller populates one
+// or more of these to describe the devices a container should receive in lieu of setting
+// NVIDIA_VISIBLE_DEVICES / NVIDIA_DRIVER_CAPABILITIES directly.
+type DeviceRequest struct {
+	// Driver is the name of the device driver that should service this request, e.g. "nvidia".
+	Driver string
+	// Count is the number of devices to select. CountAll (-1) selects all devices visible to
+	// Driver. A positive value requests that many devices be selected from those available;
+	// this is only honored when DeviceIDs is empty.
+	Count int
+	// DeviceIDs is a list of device identifiers to select. Each entry may be a device index
+	// ("0"), a GPU UUID ("GPU-fef8..."), or a PCI bus ID ("0000:65:00.0"), including MIG device
+	// identifiers. When non-empty, DeviceIDs takes precedence over Count.
+	DeviceIDs []string
+	// Capabilities is an OR-of-AND list of driver capabilities, e.g.
+	// [["gpu", "nvidia", "compute"], ["gpu", "nvidia", "utility"]]. A request is satisfiable if
+	// any one of the inner lists is fully satisfied.
+	Capabilities [][]string
+	// Options holds free-form driver-specific options that do not map onto the fields above.
+	Options map[string]string
+}
+
+// ToVisibleDevices converts a set of DeviceRequests targeting the "nvidia" driver into the
+// image.VisibleDevices representation consumed by doPrestart and the discover package. Requests
+// for other drivers are ignored.
+//
+// source is used to resolve Count > 0 requests ("pick N GPUs") by enumerating the GPUs present
+// on the host and selecting the first N by index; it may be nil if no request uses Count > 0.
+// Devices already claimed by an earlier request in the same call are not selected again.
+func ToVisibleDevices(requests []DeviceRequest, source gpu.Source) (image.VisibleDevices, error) {
+	var ids []string
+	claimed := make(map[int]bool)
+
+	for _, r := range requests {
+		if r.Driver != "" && r.Driver != "nvidia" {
+			continue
+		}
+
+		switch {
+		case len(r.DeviceIDs) > 0:
+			ids = append(ids, r.DeviceIDs...)
+		case r.Count == CountAll:
+			ids = append(ids, "all")
+		case r.Count > 0:
+			selected, err := pickGPUs(source, r.Count, claimed)
+			if err != nil {
+				return nil, fmt.Errorf("failed to select %d devices for %q: %v", r.Count, r, err)
+			}
+			ids = append(ids, selected...)
+		default:
+			return nil, fmt.Errorf("device request for driver %q specifies neither DeviceIDs nor a Count", r.Driver)
+		}
+	}
+
+	return image.NewVisibleDevices(ids...), nil
+}
+
+// pickGPUs selects the first count GPUs (by index) known to source that are not already in
+// claimed, marking them as claimed so a later request in the same call does not reselect them.
+func pickGPUs(source gpu.Source, count int, claimed map[int]bool) ([]string, error) {
+	if source == nil {
+		return nil, fmt.Errorf("no GPU source available to resolve Count > 0 without explicit DeviceIDs")
+	}
+
+	gpus, err := source.GPUs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to enumerate GPUs: %v", err)
+	}
+
+	var ids []string
+	for _, g := range gpus {
+		if len(ids) == count {
+			break
+		}
+		if claimed[g.Index] {
+			continue
+		}
+		claimed[g.Index] = true
+		ids = append(ids, strconv.Itoa(g.Index))
+	}
+
+	if len(ids) < count {
+		return nil, fmt.Errorf("requested %d devices but only %d are available", count, len(ids))
+	}
+
+	return ids, nil
+}
+
+// ToDriverCapabilities flattens the Capabilities of the supplied requests into a
+// comma-separated capability string in the NVIDIA_DRIVER_CAPABILITIES format, as consumed by
+// doPrestartNative. Since NVIDIA_DRIVER_CAPABILITIES has no OR-of-AND semantics of its own, only
+// the first AND-list on each request is used; the remaining alternatives are ignored.
+func ToDriverCapabilities(requests []DeviceRequest) string {
+	var capabilities []string
+	seen := make(map[string]bool)
+
+	for _, r := range requests {
+		if len(r.Capabilities) == 0 {
+			continue
+		}
+		for _, cap := range r.Capabilities[0] {
+			if cap == "gpu" || cap == "nvidia" || seen[cap] {
+				continue
+			}
+			seen[cap] = true
+			capabilities = append(capabilities, cap)
+		}
+	}
+
+	return strings.Join(capabilities, ",")
+}
+
+// ToRequirements extracts the requirements encoded in a request's Options under the
+// "nvidia-requirements" key, formatted as a comma-separated list of constraints
+// (e.g. "cuda>=11.0"), matching the --require flag accepted by nvidia-container-cli.
+func ToRequirements(requests []DeviceRequest) []string {
+	var requirements []string
+	for _, r := range requests {
+		raw, ok := r.Options["nvidia-requirements"]
+		if !ok || raw == "" {
+			continue
+		}
+		requirements = append(requirements, strings.Split(raw, ",")...)
+	}
+	return requirements
+}
+
+// String returns a human-readable summary of the request, useful for logging.
+func (r DeviceRequest) String() string {
+	count := strconv.Itoa(r.Count)
+	if r.Count == CountAll {
+		count = "all"
+	}
+	return fmt.Sprintf("driver=%s count=%s deviceIDs=%v capabilities=%v", r.Driver, count, r.DeviceIDs, r.Capabilities)
+}
@@ -0,0 +1,191 @@
+/**
+# Copyright (c) 2023, NVIDIA CORPORATION.  All rights reserved.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+**/
+
+package devicerequest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/NVIDIA/nvidia-container-toolkit/internal/info/gpu"
+)
+
+// fakeSource is a gpu.Source test double that returns a fixed set of GPUs.
+type fakeSource struct {
+	gpus []gpu.Info
+	err  error
+}
+
+func (s fakeSource) GPUs() ([]gpu.Info, error) {
+	return s.gpus, s.err
+}
+
+func TestToVisibleDevices(t *testing.T) {
+	source := fakeSource{gpus: []gpu.Info{{Index: 0}, {Index: 1}, {Index: 2}}}
+
+	testCases := []struct {
+		description string
+		requests    []DeviceRequest
+		source      gpu.Source
+		expected    []string
+		errExpected bool
+	}{
+		{
+			description: "explicit device IDs",
+			requests:    []DeviceRequest{{Driver: "nvidia", DeviceIDs: []string{"0", "GPU-fef8"}}},
+			expected:    []string{"0", "GPU-fef8"},
+		},
+		{
+			description: "count all",
+			requests:    []DeviceRequest{{Driver: "nvidia", Count: CountAll}},
+			expected:    []string{"all"},
+		},
+		{
+			description: "count selects first N free GPUs",
+			requests:    []DeviceRequest{{Driver: "nvidia", Count: 2}},
+			source:      source,
+			expected:    []string{"0", "1"},
+		},
+		{
+			description: "count without a source fails",
+			requests:    []DeviceRequest{{Driver: "nvidia", Count: 1}},
+			errExpected: true,
+		},
+		{
+			description: "count exceeding available GPUs fails",
+			requests:    []DeviceRequest{{Driver: "nvidia", Count: 5}},
+			source:      source,
+			errExpected: true,
+		},
+		{
+			description: "requests for other drivers are ignored",
+			requests:    []DeviceRequest{{Driver: "other", DeviceIDs: []string{"0"}}},
+			expected:    nil,
+		},
+		{
+			description: "neither DeviceIDs nor Count fails",
+			requests:    []DeviceRequest{{Driver: "nvidia"}},
+			errExpected: true,
+		},
+		{
+			description: "two Count requests do not reselect the same GPU",
+			requests: []DeviceRequest{
+				{Driver: "nvidia", Count: 1},
+				{Driver: "nvidia", Count: 1},
+			},
+			source:   source,
+			expected: []string{"0", "1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			devices, err := ToVisibleDevices(tc.requests, tc.source)
+			if tc.errExpected {
+				if err == nil {
+					t.Fatalf("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual([]string(devices), tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, devices)
+			}
+		})
+	}
+}
+
+func TestToDriverCapabilities(t *testing.T) {
+	testCases := []struct {
+		description string
+		requests    []DeviceRequest
+		expected    string
+	}{
+		{
+			description: "no requests",
+			requests:    nil,
+			expected:    "",
+		},
+		{
+			description: "gpu and nvidia are filtered out",
+			requests:    []DeviceRequest{{Capabilities: [][]string{{"gpu", "nvidia", "compute"}}}},
+			expected:    "compute",
+		},
+		{
+			description: "only the first AND-list is used",
+			requests: []DeviceRequest{{
+				Capabilities: [][]string{
+					{"gpu", "nvidia", "compute"},
+					{"gpu", "nvidia", "utility"},
+				},
+			}},
+			expected: "compute",
+		},
+		{
+			description: "capabilities across requests are deduplicated",
+			requests: []DeviceRequest{
+				{Capabilities: [][]string{{"gpu", "nvidia", "compute"}}},
+				{Capabilities: [][]string{{"gpu", "nvidia", "compute", "utility"}}},
+			},
+			expected: "compute,utility",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := ToDriverCapabilities(tc.requests); got != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestToRequirements(t *testing.T) {
+	testCases := []struct {
+		description string
+		requests    []DeviceRequest
+		expected    []string
+	}{
+		{
+			description: "no options",
+			requests:    []DeviceRequest{{}},
+			expected:    nil,
+		},
+		{
+			description: "single requirement",
+			requests:    []DeviceRequest{{Options: map[string]string{"nvidia-requirements": "cuda>=11.0"}}},
+			expected:    []string{"cuda>=11.0"},
+		},
+		{
+			description: "multiple requirements across requests",
+			requests: []DeviceRequest{
+				{Options: map[string]string{"nvidia-requirements": "cuda>=11.0,driver>=450"}},
+				{Options: map[string]string{"nvidia-requirements": "arch>=sm_75"}},
+			},
+			expected: []string{"cuda>=11.0", "driver>=450", "arch>=sm_75"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := ToRequirements(tc.requests); !reflect.DeepEqual(got, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}